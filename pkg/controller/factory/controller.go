@@ -0,0 +1,371 @@
+package factory
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/apiserver/pkg/server/healthz"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/leaderelection"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog/v2"
+
+	"github.com/openshift/library-go/pkg/operator/events"
+)
+
+// controller is the default Controller implementation produced by Factory.ToController().
+type controller struct {
+	name           string
+	sync           SyncFunc
+	resyncInterval time.Duration
+
+	informerRegistrations []informerRegistration
+
+	queue         workqueue.RateLimitingInterface
+	eventRecorder events.Recorder
+
+	postStartHooks []PostStartHook
+	healthCheckers []healthz.HealthChecker
+	syncStats      syncStats
+
+	leaderElectionConfig *leaderelection.LeaderElectionConfig
+	onStartedLeading     func(ctx context.Context)
+	onStoppedLeading     func()
+
+	maxRetries        int
+	deadLetterHandler DeadLetterHandler
+
+	// logger is the base logger for this controller. When empty, Run derives it from
+	// klog.FromContext(ctx) instead.
+	logger logr.Logger
+}
+
+var _ Controller = &controller{}
+
+func newDefaultQueue(name string) workqueue.RateLimitingInterface {
+	return workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), name)
+}
+
+func (c *controller) Name() string {
+	return c.name
+}
+
+// Queue returns the workqueue the controller pops keys from.
+func (c *controller) Queue() workqueue.RateLimitingInterface {
+	return c.queue
+}
+
+// PrepareRun waits for the controller's informers to sync and registers their event handlers. See the
+// Controller interface doc for why this is split out of Run.
+func (c *controller) PrepareRun(ctx context.Context) bool {
+	logger := c.logger
+	if logger.GetSink() == nil {
+		logger = klog.FromContext(ctx)
+	}
+	logger = logger.WithValues("controller", c.name)
+	c.logger = logger
+
+	for _, informer := range c.allInformers() {
+		if !cache.WaitForCacheSync(ctx.Done(), informer.HasSynced) {
+			logger.V(2).Info("stopped waiting for informer caches to sync, context was cancelled")
+			return false
+		}
+	}
+	c.registerEventHandlers(logger)
+	return true
+}
+
+// Run starts workers workers and blocks until ctx is cancelled and every worker has returned. With leader
+// election configured, Run also returns once the lease is lost (after every worker has returned), or right
+// away if the leader elector cannot be created.
+func (c *controller) Run(ctx context.Context, workers int) {
+	defer utilruntime.HandleCrash()
+
+	// shut the queue down as soon as we are told to stop, so that workers currently blocked in
+	// queue.Get() (or about to call it once their in-flight sync returns) unblock immediately instead of
+	// waiting for every worker to drain on its own.
+	go func() {
+		<-ctx.Done()
+		c.queue.ShutDown()
+	}()
+
+	if !c.PrepareRun(ctx) {
+		return
+	}
+	logger := c.logger
+
+	logger.V(2).Info("starting controller")
+	defer logger.V(2).Info("shutting down controller")
+
+	if c.leaderElectionConfig == nil {
+		c.runWorkersUntilDone(ctx, workers)
+		return
+	}
+	c.runWithLeaderElection(ctx, workers, logger)
+}
+
+// runWorkersUntilDone runs the post-start hooks, starts the resync loop and workers workers, and blocks
+// until ctx is cancelled and every worker has returned. It is the part of Run that only happens while the
+// controller is allowed to actually do work: unconditionally for a controller without leader election, or
+// only while holding the lease for one configured with WithLeaderElection.
+func (c *controller) runWorkersUntilDone(ctx context.Context, workers int) {
+	logger := c.logger
+
+	c.runPostStartHooks(ctx, &syncContext{
+		queue:    c.queue,
+		queueKey: DefaultQueueKey,
+		recorder: c.eventRecorder,
+		logger:   logger,
+	})
+
+	if c.resyncInterval > 0 {
+		go wait.Until(func() {
+			logger.V(4).Info("resyncing", "interval", c.resyncInterval)
+			c.queue.Add(DefaultQueueKey)
+		}, c.resyncInterval, ctx.Done())
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		workerIndex := i
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			wait.Until(func() { c.runWorker(ctx, workerIndex) }, time.Second, ctx.Done())
+		}()
+	}
+
+	<-ctx.Done()
+	wg.Wait()
+}
+
+// runWithLeaderElection wraps runWorkersUntilDone in a leaderelection.LeaderElector built from
+// c.leaderElectionConfig. Workers only run while this controller holds the lease; the context they run
+// under is the one the elector hands to OnStartedLeading, which it cancels once the lease is lost. Losing
+// the lease shuts the queue down so any worker blocked in Queue().Get() unblocks immediately - this
+// controller does not attempt to reacquire the lease afterwards.
+func (c *controller) runWithLeaderElection(ctx context.Context, workers int, logger logr.Logger) {
+	electorCtx, cancelElector := context.WithCancel(ctx)
+	defer cancelElector()
+
+	// leaderelection.LeaderElector runs OnStartedLeading in its own goroutine and does not wait for it
+	// before Run returns, so workersDone is what lets OnStoppedLeading - which does run synchronously on
+	// Run's own goroutine, before Run returns - block until runWorkersUntilDone has actually finished.
+	// That keeps Run's "blocks until every worker has returned" contract even with leader election.
+	// stoppedLeading, guarded by leadingLock, keeps a late OnStartedLeading from adding to workersDone after
+	// OnStoppedLeading has already started waiting on it.
+	var workersDone sync.WaitGroup
+	var leadingLock sync.Mutex
+	stoppedLeading := false
+
+	config := *c.leaderElectionConfig
+	userCallbacks := config.Callbacks
+	config.Callbacks = leaderelection.LeaderCallbacks{
+		OnStartedLeading: func(leaseCtx context.Context) {
+			leadingLock.Lock()
+			if stoppedLeading {
+				leadingLock.Unlock()
+				return
+			}
+			workersDone.Add(1)
+			leadingLock.Unlock()
+			defer workersDone.Done()
+
+			logger.V(2).Info("acquired leader lease")
+			if userCallbacks.OnStartedLeading != nil {
+				userCallbacks.OnStartedLeading(leaseCtx)
+			}
+			if c.onStartedLeading != nil {
+				c.onStartedLeading(leaseCtx)
+			}
+			c.runWorkersUntilDone(leaseCtx, workers)
+		},
+		OnStoppedLeading: func() {
+			logger.V(2).Info("lost leader lease, shutting down")
+			c.queue.ShutDown()
+			if c.onStoppedLeading != nil {
+				c.onStoppedLeading()
+			}
+			if userCallbacks.OnStoppedLeading != nil {
+				userCallbacks.OnStoppedLeading()
+			}
+			cancelElector()
+			leadingLock.Lock()
+			stoppedLeading = true
+			leadingLock.Unlock()
+			workersDone.Wait()
+		},
+		OnNewLeader: userCallbacks.OnNewLeader,
+	}
+
+	elector, err := leaderelection.NewLeaderElector(config)
+	if err != nil {
+		utilruntime.HandleError(fmt.Errorf("controller %q: failed to create leader elector: %w", c.name, err))
+		return
+	}
+	elector.Run(electorCtx)
+}
+
+func (c *controller) allInformers() []cache.SharedIndexInformer {
+	informers := make([]cache.SharedIndexInformer, 0, len(c.informerRegistrations))
+	for _, reg := range c.informerRegistrations {
+		informers = append(informers, reg.informer)
+	}
+	return informers
+}
+
+// asRuntimeObject unwraps the object an informer event handler receives into a runtime.Object, following
+// through a cache.DeletedFinalStateUnknown tombstone for a Delete event that arrived after we missed the
+// actual delete.
+func asRuntimeObject(obj interface{}) runtime.Object {
+	if runtimeObj, ok := obj.(runtime.Object); ok {
+		return runtimeObj
+	}
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		if runtimeObj, ok := tombstone.Obj.(runtime.Object); ok {
+			return runtimeObj
+		}
+	}
+	return nil
+}
+
+// queueKeysFor computes the queue keys reg wants enqueued for obj, applying its queueKeysFn/queueKeyFn, or
+// falling back to DefaultQueueKey when neither is set.
+func queueKeysFor(reg informerRegistration, obj interface{}) []string {
+	runtimeObj := asRuntimeObject(obj)
+	switch {
+	case reg.queueKeysFn != nil && runtimeObj != nil:
+		return reg.queueKeysFn(runtimeObj)
+	case reg.queueKeyFn != nil && runtimeObj != nil:
+		return []string{reg.queueKeyFn(runtimeObj)}
+	default:
+		return []string{DefaultQueueKey}
+	}
+}
+
+func (c *controller) registerEventHandlers(logger logr.Logger) {
+	enqueueKeys := func(keys []string) {
+		for _, key := range keys {
+			logger.V(4).Info("queueing key in response to informer event", "key", key)
+			c.queue.Add(key)
+		}
+	}
+
+	for _, reg := range c.informerRegistrations {
+		reg := reg
+		reg.informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+			AddFunc: func(obj interface{}) {
+				if reg.filter != nil && !reg.filter(nil, obj) {
+					return
+				}
+				enqueueKeys(queueKeysFor(reg, obj))
+			},
+			UpdateFunc: func(old, new interface{}) {
+				if reg.filter != nil && !reg.filter(old, new) {
+					return
+				}
+				enqueueKeys(queueKeysFor(reg, new))
+			},
+			DeleteFunc: func(obj interface{}) {
+				if reg.filter != nil && !reg.filter(obj, nil) {
+					return
+				}
+				enqueueKeys(queueKeysFor(reg, obj))
+			},
+		})
+	}
+}
+
+func (c *controller) runWorker(ctx context.Context, workerIndex int) {
+	logger := c.logger.WithValues("worker", workerIndex)
+	ctx = klog.NewContext(ctx, logger)
+	logger.V(4).Info("starting worker")
+	defer logger.V(4).Info("shutting down worker")
+
+	for c.processNextWorkItem(ctx) {
+	}
+}
+
+func (c *controller) processNextWorkItem(ctx context.Context) bool {
+	key, shutdown := c.queue.Get()
+	if shutdown {
+		return false
+	}
+	defer c.queue.Done(key)
+
+	if err := c.Sync(ctx, key.(string)); err != nil {
+		if c.maxRetries > 0 && c.queue.NumRequeues(key) >= c.maxRetries-1 {
+			c.giveUpOn(ctx, key.(string), err)
+			return true
+		}
+		c.queue.AddRateLimited(key)
+		return true
+	}
+
+	c.queue.Forget(key)
+	return true
+}
+
+// giveUpOn forgets key instead of requeuing it once its retry budget set by Factory.WithMaxRetries is
+// exhausted, bumps controller_key_dead_letter_total and, if one was registered, calls the controller's
+// DeadLetterHandler with lastErr.
+func (c *controller) giveUpOn(ctx context.Context, key string, lastErr error) {
+	c.queue.Forget(key)
+	deadLetterTotal.WithLabelValues(c.name).Inc()
+	c.logger.WithValues("key", key).Error(lastErr, "giving up on key after reaching max retries", "maxRetries", c.maxRetries)
+	if c.deadLetterHandler != nil {
+		c.deadLetterHandler(ctx, key, lastErr)
+	}
+}
+
+// Sync invokes the controller's configured SyncFunc directly for key. If ctx already carries a logger (as
+// it does when called from a running worker), that logger is used as the base so "worker"-style fields
+// survive; otherwise the controller's own base logger is used. It exists so Run's workers and test
+// harnesses like factory/factorytesting can share a single code path for actually calling the SyncFunc.
+func (c *controller) Sync(ctx context.Context, key string) error {
+	logger, err := logr.FromContext(ctx)
+	if err != nil {
+		logger = c.logger
+	}
+	if logger.GetSink() == nil {
+		logger = klog.Background()
+	}
+	logger = logger.WithValues("key", key)
+	ctx = klog.NewContext(ctx, logger)
+
+	syncContext := &syncContext{
+		queue:    c.queue,
+		queueKey: key,
+		recorder: c.eventRecorder,
+		logger:   logger,
+	}
+
+	logger.V(4).Info("syncing key")
+	syncErr := c.sync(ctx, syncContext)
+	c.syncStats.recordSync(syncErr)
+	if syncErr != nil {
+		logger.Error(syncErr, "sync failed")
+	}
+	return syncErr
+}
+
+// syncContext is the default SyncContext implementation handed to a SyncFunc.
+type syncContext struct {
+	queue    workqueue.RateLimitingInterface
+	queueKey string
+	recorder events.Recorder
+	logger   logr.Logger
+}
+
+var _ SyncContext = &syncContext{}
+
+func (s *syncContext) Queue() workqueue.RateLimitingInterface { return s.queue }
+func (s *syncContext) QueueKey() string                       { return s.queueKey }
+func (s *syncContext) Recorder() events.Recorder              { return s.recorder }
+func (s *syncContext) Logger() logr.Logger                    { return s.logger }
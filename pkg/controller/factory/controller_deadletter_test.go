@@ -0,0 +1,64 @@
+package factory
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/openshift/library-go/pkg/operator/events"
+)
+
+// TestControllerDeadLettersAfterMaxRetries seeds a sync that always fails and asserts the controller gives
+// up on the key after WithMaxRetries syncs, calling the DeadLetterHandler exactly once.
+func TestControllerDeadLettersAfterMaxRetries(t *testing.T) {
+	syncErr := errors.New("permanent failure")
+
+	var lock sync.Mutex
+	var once sync.Once
+	syncCount := 0
+	var deadLetterKeys []string
+	deadLettered := make(chan struct{})
+
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+
+	controller := New().
+		WithMaxRetries(3).
+		WithDeadLetterHandler(func(ctx context.Context, key string, lastErr error) {
+			lock.Lock()
+			defer lock.Unlock()
+			deadLetterKeys = append(deadLetterKeys, key)
+			if lastErr != syncErr {
+				t.Errorf("expected dead letter handler to see %v, got %v", syncErr, lastErr)
+			}
+			once.Do(func() { close(deadLettered) })
+		}).
+		WithSync(func(ctx context.Context, syncContext SyncContext) error {
+			lock.Lock()
+			syncCount++
+			lock.Unlock()
+			return syncErr
+		}).
+		ToController("dead-letter-controller", events.NewInMemoryRecorder("dead-letter-controller"))
+
+	go controller.Run(ctx, 1)
+	controller.Queue().Add(DefaultQueueKey)
+
+	select {
+	case <-deadLettered:
+		cancel()
+	case <-time.After(10 * time.Second):
+		t.Fatal("dead letter handler never fired")
+	}
+
+	lock.Lock()
+	defer lock.Unlock()
+	if len(deadLetterKeys) != 1 || deadLetterKeys[0] != DefaultQueueKey {
+		t.Fatalf("expected dead letter handler to fire exactly once for key %q, got %v", DefaultQueueKey, deadLetterKeys)
+	}
+	if syncCount != 3 {
+		t.Errorf("expected exactly 3 syncs before giving up (maxRetries=3), got %d", syncCount)
+	}
+}
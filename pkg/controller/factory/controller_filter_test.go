@@ -0,0 +1,107 @@
+package factory
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/openshift/library-go/pkg/operator/events"
+)
+
+func TestControllerWithFilteredEventsInformers(t *testing.T) {
+	kubeClient := fake.NewSimpleClientset()
+	kubeInformers := informers.NewSharedInformerFactoryWithOptions(kubeClient, 1*time.Minute, informers.WithNamespace("test"))
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+	go kubeInformers.Start(ctx.Done())
+
+	onlyInteresting := func(oldObj, newObj interface{}) bool {
+		secret, ok := newObj.(*v1.Secret)
+		return ok && secret.Name == "interesting-secret"
+	}
+
+	factory := New().WithFilteredEventsInformers(onlyInteresting, kubeInformers.Core().V1().Secrets().Informer())
+
+	seenKeys := make(chan string, 10)
+	controller := factory.WithSync(func(ctx context.Context, syncContext SyncContext) error {
+		seenKeys <- syncContext.QueueKey()
+		return nil
+	}).ToController("FilteredController", events.NewInMemoryRecorder("filtered-controller"))
+
+	go controller.Run(ctx, 1)
+	time.Sleep(1 * time.Second) // Give controller time to start
+
+	boring := makeFakeSecret()
+	boring.Name = "boring-secret"
+	if _, err := kubeClient.CoreV1().Secrets("test").Create(context.TODO(), boring, meta.CreateOptions{}); err != nil {
+		t.Fatalf("failed to create boring secret: %v", err)
+	}
+
+	interesting := makeFakeSecret()
+	interesting.Name = "interesting-secret"
+	if _, err := kubeClient.CoreV1().Secrets("test").Create(context.TODO(), interesting, meta.CreateOptions{}); err != nil {
+		t.Fatalf("failed to create interesting secret: %v", err)
+	}
+
+	select {
+	case key := <-seenKeys:
+		if key != DefaultQueueKey {
+			t.Errorf("expected queue key %q, got %q", DefaultQueueKey, key)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("filtered controller never synced for the interesting secret")
+	}
+
+	select {
+	case key := <-seenKeys:
+		t.Fatalf("boring secret should have been filtered out, but it enqueued key %q", key)
+	case <-time.After(2 * time.Second):
+	}
+}
+
+func TestControllerWithInformersQueueKeysFunc(t *testing.T) {
+	kubeClient := fake.NewSimpleClientset()
+	kubeInformers := informers.NewSharedInformerFactoryWithOptions(kubeClient, 1*time.Minute, informers.WithNamespace("test"))
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+	go kubeInformers.Start(ctx.Done())
+
+	fanOut := func(obj runtime.Object) []string {
+		return []string{"owner-a", "owner-b"}
+	}
+
+	factory := New().WithInformersQueueKeysFunc(fanOut, kubeInformers.Core().V1().Secrets().Informer())
+
+	seenKeys := make(chan string, 10)
+	controller := factory.WithSync(func(ctx context.Context, syncContext SyncContext) error {
+		seenKeys <- syncContext.QueueKey()
+		return nil
+	}).ToController("FanOutController", events.NewInMemoryRecorder("fan-out-controller"))
+
+	go controller.Run(ctx, 2)
+	time.Sleep(1 * time.Second) // Give controller time to start
+
+	if _, err := kubeClient.CoreV1().Secrets("test").Create(context.TODO(), makeFakeSecret(), meta.CreateOptions{}); err != nil {
+		t.Fatalf("failed to create fake secret: %v", err)
+	}
+
+	seen := map[string]bool{}
+	for i := 0; i < 2; i++ {
+		select {
+		case key := <-seenKeys:
+			seen[key] = true
+		case <-time.After(10 * time.Second):
+			t.Fatalf("timed out waiting for fanned-out keys, got %v so far", seen)
+		}
+	}
+
+	if !seen["owner-a"] || !seen["owner-b"] {
+		t.Errorf("expected both fanned-out keys to be synced, got %v", seen)
+	}
+}
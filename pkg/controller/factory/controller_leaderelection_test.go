@@ -0,0 +1,88 @@
+package factory
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/leaderelection"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+
+	"github.com/openshift/library-go/pkg/operator/events"
+)
+
+func newTestLeaderElectionConfig(t *testing.T, identity string) leaderelection.LeaderElectionConfig {
+	t.Helper()
+
+	kubeClient := fake.NewSimpleClientset()
+	lock, err := resourcelock.New(
+		resourcelock.LeasesResourceLock,
+		"test-namespace",
+		"test-controller",
+		kubeClient.CoreV1(),
+		kubeClient.CoordinationV1(),
+		resourcelock.ResourceLockConfig{Identity: identity},
+	)
+	if err != nil {
+		t.Fatalf("failed to build resource lock: %v", err)
+	}
+
+	return leaderelection.LeaderElectionConfig{
+		Lock:          lock,
+		LeaseDuration: 2 * time.Second,
+		RenewDeadline: time.Second,
+		RetryPeriod:   200 * time.Millisecond,
+	}
+}
+
+// TestControllerWithLeaderElectionSyncsAndShutsDown verifies that a controller built with
+// WithLeaderElection syncs after acquiring the lease, and that cancelling the context it was run with stops
+// its workers and makes Run return, the same way TestMultiWorkerControllerShutdown expects for a controller
+// without leader election.
+func TestControllerWithLeaderElectionSyncsAndShutsDown(t *testing.T) {
+	var syncCount int32
+	c := New().
+		WithLeaderElection(newTestLeaderElectionConfig(t, "test-identity")).
+		WithSync(func(ctx context.Context, syncContext SyncContext) error {
+			atomic.AddInt32(&syncCount, 1)
+			return nil
+		}).
+		ToController("test-controller", events.NewInMemoryRecorder("test"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	runDone := make(chan struct{})
+	go func() {
+		defer close(runDone)
+		c.Run(ctx, 1)
+	}()
+
+	if !waitFor(t, 5*time.Second, func() bool {
+		c.Queue().Add(DefaultQueueKey)
+		return atomic.LoadInt32(&syncCount) > 0
+	}) {
+		t.Fatal("controller never synced after acquiring the lease")
+	}
+
+	cancel()
+
+	select {
+	case <-runDone:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run did not return after its context was cancelled")
+	}
+}
+
+func waitFor(t *testing.T, timeout time.Duration, condition func() bool) bool {
+	t.Helper()
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		if condition() {
+			return true
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	return false
+}
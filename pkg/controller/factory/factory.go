@@ -0,0 +1,172 @@
+package factory
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apiserver/pkg/server/healthz"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/leaderelection"
+
+	"github.com/openshift/library-go/pkg/operator/events"
+)
+
+// informerRegistration pairs an informer with the (optional) ObjectQueueKeyFunc/ObjectQueueKeysFunc used
+// to compute the queue key(s) for the objects it surfaces and the (optional) EventFilterFunc used to
+// decide whether an event should enqueue anything at all. queueKeyFn and queueKeysFn are mutually
+// exclusive; when neither is set, events enqueue DefaultQueueKey.
+type informerRegistration struct {
+	informer    cache.SharedIndexInformer
+	queueKeyFn  ObjectQueueKeyFunc
+	queueKeysFn ObjectQueueKeysFunc
+	filter      EventFilterFunc
+}
+
+// Factory is a generator for simple, queue-driven Kubernetes controllers. It is meant to cover the common
+// case of "watch a handful of informers, compute a key, call a sync function" so individual controllers do
+// not have to hand-roll workqueue plumbing.
+//
+// A Factory is configured via its With* methods, each of which returns the Factory for chaining, and
+// finalized by calling ToController().
+type Factory struct {
+	sync                  SyncFunc
+	resyncInterval        time.Duration
+	informerRegistrations []informerRegistration
+	logger                logr.Logger
+	postStartHooks        []PostStartHook
+	healthCheckers        []healthz.HealthChecker
+
+	leaderElectionConfig *leaderelection.LeaderElectionConfig
+	onStartedLeading     func(ctx context.Context)
+	onStoppedLeading     func()
+
+	maxRetries        int
+	deadLetterHandler DeadLetterHandler
+}
+
+// New returns a new, empty Factory.
+func New() *Factory {
+	return &Factory{}
+}
+
+// WithSync sets the function that is called whenever the controller processes a key from its queue.
+func (f *Factory) WithSync(syncFn SyncFunc) *Factory {
+	f.sync = syncFn
+	return f
+}
+
+// ResyncEvery makes the controller enqueue DefaultQueueKey on the given interval, in addition to whatever
+// informer events trigger a sync.
+func (f *Factory) ResyncEvery(interval time.Duration) *Factory {
+	f.resyncInterval = interval
+	return f
+}
+
+// WithInformers registers informers whose Add/Update/Delete events enqueue DefaultQueueKey. Use this when
+// the controller does not care which object changed, only that something did.
+func (f *Factory) WithInformers(informers ...cache.SharedIndexInformer) *Factory {
+	for _, informer := range informers {
+		f.informerRegistrations = append(f.informerRegistrations, informerRegistration{informer: informer})
+	}
+	return f
+}
+
+// WithInformersQueueKeyFunc registers informers whose Add/Update/Delete events enqueue the key computed by
+// queueKeyFn for the changed object.
+func (f *Factory) WithInformersQueueKeyFunc(queueKeyFn ObjectQueueKeyFunc, informers ...cache.SharedIndexInformer) *Factory {
+	for _, informer := range informers {
+		f.informerRegistrations = append(f.informerRegistrations, informerRegistration{informer: informer, queueKeyFn: queueKeyFn})
+	}
+	return f
+}
+
+// WithInformersQueueKeysFunc registers informers whose Add/Update/Delete events enqueue every key computed
+// by queueKeysFn for the changed object, letting one object fan out into several keys.
+func (f *Factory) WithInformersQueueKeysFunc(queueKeysFn ObjectQueueKeysFunc, informers ...cache.SharedIndexInformer) *Factory {
+	for _, informer := range informers {
+		f.informerRegistrations = append(f.informerRegistrations, informerRegistration{informer: informer, queueKeysFn: queueKeysFn})
+	}
+	return f
+}
+
+// WithFilteredEventsInformers registers informers whose Add/Update/Delete events enqueue DefaultQueueKey,
+// but only when filter returns true for the (old, new) object pair. Use this instead of WithInformers to
+// avoid waking up workers for events the sync function would just filter out itself.
+func (f *Factory) WithFilteredEventsInformers(filter EventFilterFunc, informers ...cache.SharedIndexInformer) *Factory {
+	for _, informer := range informers {
+		f.informerRegistrations = append(f.informerRegistrations, informerRegistration{informer: informer, filter: filter})
+	}
+	return f
+}
+
+// WithLeaderElection makes the resulting controller only start its workers and resync loop while it holds
+// the lease described by config; losing or failing to renew the lease cancels the context those workers
+// run under, so they shut down the same way TestMultiWorkerControllerShutdown expects. config.Callbacks is
+// honored in addition to the controller's own bookkeeping, and is the right place for e.g. readiness gate
+// flips; use WithLeaderElectionCallbacks for hooks that don't need a full LeaderCallbacks.
+func (f *Factory) WithLeaderElection(config leaderelection.LeaderElectionConfig) *Factory {
+	f.leaderElectionConfig = &config
+	return f
+}
+
+// WithLeaderElectionCallbacks registers additional hooks run when this controller starts or stops leading,
+// alongside whatever Callbacks were set on the LeaderElectionConfig passed to WithLeaderElection. Either
+// argument may be nil. onStartedLeading is called with the context workers run under; it is cancelled once
+// the lease is lost.
+func (f *Factory) WithLeaderElectionCallbacks(onStartedLeading func(ctx context.Context), onStoppedLeading func()) *Factory {
+	f.onStartedLeading = onStartedLeading
+	f.onStoppedLeading = onStoppedLeading
+	return f
+}
+
+// WithMaxRetries bounds how many times the controller retries a key that keeps failing: once a key has
+// failed maxRetries times, it is forgotten from the queue instead of requeued again, and reported to the
+// handler set via WithDeadLetterHandler, if any. A maxRetries of 0 (the default) means unbounded retries.
+func (f *Factory) WithMaxRetries(maxRetries int) *Factory {
+	f.maxRetries = maxRetries
+	return f
+}
+
+// WithDeadLetterHandler registers a DeadLetterHandler to call for a key once WithMaxRetries' budget for it
+// is exhausted. It has no effect unless WithMaxRetries is also called with a value greater than 0.
+func (f *Factory) WithDeadLetterHandler(handler DeadLetterHandler) *Factory {
+	f.deadLetterHandler = handler
+	return f
+}
+
+// WithLogger overrides the logr.Logger the resulting controller uses as the base for its contextual
+// logging. When this is not called, the controller derives its logger from klog.FromContext(ctx) at Run
+// time instead.
+func (f *Factory) WithLogger(logger logr.Logger) *Factory {
+	f.logger = logger
+	return f
+}
+
+// ToController builds the Controller out of the current Factory configuration. name is used both as the
+// controller's Name() and as a "controller" key/value pair on every log line it produces. eventRecorder is
+// used, with name as its component suffix, to record Kubernetes events about the controller's activity.
+func (f *Factory) ToController(name string, eventRecorder events.Recorder) Controller {
+	if f.sync == nil {
+		panic("WithSync must be called before ToController")
+	}
+
+	c := &controller{
+		name:                  name,
+		sync:                  f.sync,
+		resyncInterval:        f.resyncInterval,
+		informerRegistrations: f.informerRegistrations,
+		eventRecorder:         eventRecorder.WithComponentSuffix(name),
+		logger:                f.logger,
+		queue:                 newDefaultQueue(name),
+		postStartHooks:        f.postStartHooks,
+		healthCheckers:        f.healthCheckers,
+		leaderElectionConfig:  f.leaderElectionConfig,
+		onStartedLeading:      f.onStartedLeading,
+		onStoppedLeading:      f.onStoppedLeading,
+		maxRetries:            f.maxRetries,
+		deadLetterHandler:     f.deadLetterHandler,
+	}
+
+	return c
+}
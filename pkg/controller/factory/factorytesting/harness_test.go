@@ -0,0 +1,98 @@
+package factorytesting
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/openshift/library-go/pkg/controller/factory"
+)
+
+func TestTestControllerDrainsQueuedKeys(t *testing.T) {
+	var synced []string
+	f := factory.New().WithSync(func(ctx context.Context, syncContext factory.SyncContext) error {
+		synced = append(synced, syncContext.QueueKey())
+		return nil
+	})
+
+	results := TestController(t, f, WithQueueKeys("foo", "bar"))
+
+	if len(results) != 2 {
+		t.Fatalf("expected 2 results, got %d: %v", len(results), results)
+	}
+	for _, result := range results {
+		if result.Err != nil {
+			t.Errorf("expected key %q to sync without error, got: %v", result.Key, result.Err)
+		}
+	}
+	if len(synced) != 2 || synced[0] != "foo" || synced[1] != "bar" {
+		t.Errorf("expected sync to see keys [foo bar] in order, got %v", synced)
+	}
+}
+
+func TestTestControllerCollectsSyncErrors(t *testing.T) {
+	f := factory.New().WithSync(func(ctx context.Context, syncContext factory.SyncContext) error {
+		return errors.New("synthetic failure")
+	})
+
+	results := TestController(t, f, WithQueueKeys("foo"))
+
+	if len(results) != 1 {
+		t.Fatalf("expected 1 result, got %d: %v", len(results), results)
+	}
+	if results[0].Err == nil {
+		t.Error("expected a sync error to be reported")
+	}
+}
+
+func TestTestControllerWaitsForInformerSync(t *testing.T) {
+	secret := &v1.Secret{ObjectMeta: meta.ObjectMeta{Name: "test-secret", Namespace: "test"}}
+	kubeClient := fake.NewSimpleClientset(secret)
+	kubeInformers := informers.NewSharedInformerFactoryWithOptions(kubeClient, time.Minute, informers.WithNamespace("test"))
+	secretInformer := kubeInformers.Core().V1().Secrets().Informer()
+
+	f := factory.New().WithInformers(secretInformer).WithSync(func(ctx context.Context, syncContext factory.SyncContext) error {
+		_, err := kubeClient.CoreV1().Secrets("test").Get(ctx, "test-secret", meta.GetOptions{})
+		return err
+	})
+
+	// No WithQueueKeys here: the informer already has test-secret in its store by the time PrepareRun
+	// registers its event handler, so that handler's replay of the existing store is what enqueues
+	// DefaultQueueKey. Queueing it a second time ourselves would race the replay, which runs on the
+	// informer's own listener goroutine independently of the harness's synchronous drain loop.
+	results := TestController(t, f,
+		WithInformerStart(kubeInformers.Start),
+	)
+
+	if len(results) != 1 || results[0].Err != nil {
+		t.Fatalf("expected one successful sync, got %v", results)
+	}
+}
+
+func TestTestControllerSeedsCacheObjects(t *testing.T) {
+	secret := &v1.Secret{ObjectMeta: meta.ObjectMeta{Name: "test-secret", Namespace: "test"}}
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	lister := corelisters.NewSecretLister(indexer)
+
+	f := factory.New().WithSync(func(ctx context.Context, syncContext factory.SyncContext) error {
+		_, err := lister.Secrets("test").Get("test-secret")
+		return err
+	})
+
+	results := TestController(t, f,
+		WithCacheObjects(indexer, secret),
+		WithQueueKeys(factory.DefaultQueueKey),
+	)
+
+	if len(results) != 1 || results[0].Err != nil {
+		t.Fatalf("expected one successful sync, got %v", results)
+	}
+}
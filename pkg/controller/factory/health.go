@@ -0,0 +1,139 @@
+package factory
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apiserver/pkg/server/healthz"
+)
+
+// maxConsecutiveSyncErrors is the number of consecutive failed syncs a controller tolerates before its
+// "sync" health check starts reporting unhealthy. A handful of transient failures (a stale cache, a
+// flaky apiserver call) should not flip a controller's readiness; a controller that has been failing for
+// this many syncs in a row is actually stuck.
+const maxConsecutiveSyncErrors = 5
+
+// PostStartHook is run once, in its own goroutine, after the controller's informer caches have synced. It
+// is started just before the workers and is not waited for, so workers may process keys before or while it
+// runs. The SyncContext passed to it is the same kind a SyncFunc receives, so a hook can use its Queue(),
+// Recorder() and Logger() to seed state or schedule an initial key; its Logger() carries only the
+// controller name, not a worker index or queue key. A returned error is logged and recorded as an event; it does not prevent the controller
+// from starting.
+type PostStartHook func(ctx context.Context, syncContext SyncContext) error
+
+// WithPostStartHook registers a PostStartHook to run when the controller starts. It may be called more
+// than once to register multiple hooks; they run concurrently with each other and with the first workers.
+func (f *Factory) WithPostStartHook(hook PostStartHook) *Factory {
+	f.postStartHooks = append(f.postStartHooks, hook)
+	return f
+}
+
+// WithHealthChecks adds extra healthz.HealthChecker instances to the ones the controller already exposes
+// for "has synced at least once", "informers synced" and "not failing maxConsecutiveSyncErrors syncs in a
+// row". This lets
+// a controller contribute additional, domain-specific checks to the same Controller.HealthChecks() list.
+func (f *Factory) WithHealthChecks(checkers ...healthz.HealthChecker) *Factory {
+	f.healthCheckers = append(f.healthCheckers, checkers...)
+	return f
+}
+
+// syncStats tracks the bookkeeping backing LastSyncError, LastSyncTime and the built-in health checks. It
+// is safe for concurrent use.
+type syncStats struct {
+	lock sync.RWMutex
+
+	hasSynced         bool
+	lastSyncTime      time.Time
+	lastSyncError     error
+	consecutiveErrors int
+}
+
+func (s *syncStats) recordSync(err error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.hasSynced = true
+	s.lastSyncTime = time.Now()
+	s.lastSyncError = err
+	if err != nil {
+		s.consecutiveErrors++
+	} else {
+		s.consecutiveErrors = 0
+	}
+}
+
+func (s *syncStats) lastSync() (time.Time, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return s.lastSyncTime, s.lastSyncError
+}
+
+func (s *syncStats) snapshot() (hasSynced bool, consecutiveErrors int, lastSyncError error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return s.hasSynced, s.consecutiveErrors, s.lastSyncError
+}
+
+// LastSyncError returns the error returned by the most recently completed sync, or nil if the last sync
+// succeeded or no sync has completed yet.
+func (c *controller) LastSyncError() error {
+	_, err := c.syncStats.lastSync()
+	return err
+}
+
+// LastSyncTime returns the time the most recently completed sync finished, or the zero time if no sync
+// has completed yet.
+func (c *controller) LastSyncTime() time.Time {
+	t, _ := c.syncStats.lastSync()
+	return t
+}
+
+// HealthChecks returns the healthz.HealthChecker instances for this controller: whether it has completed
+// at least one sync, whether its informers have synced, whether it has not failed its last
+// maxConsecutiveSyncErrors syncs in a row, plus any extra checkers registered through Factory.WithHealthChecks.
+func (c *controller) HealthChecks() []healthz.HealthChecker {
+	checks := []healthz.HealthChecker{
+		healthz.NamedCheck(c.name+"-has-synced", func(_ *http.Request) error {
+			hasSynced, _, _ := c.syncStats.snapshot()
+			if !hasSynced {
+				return fmt.Errorf("controller %q has not completed its initial sync yet", c.name)
+			}
+			return nil
+		}),
+		healthz.NamedCheck(c.name+"-informers-synced", func(_ *http.Request) error {
+			for _, informer := range c.allInformers() {
+				if !informer.HasSynced() {
+					return fmt.Errorf("controller %q has informers that have not synced yet", c.name)
+				}
+			}
+			return nil
+		}),
+		healthz.NamedCheck(c.name+"-sync", func(_ *http.Request) error {
+			_, consecutiveErrors, lastErr := c.syncStats.snapshot()
+			if consecutiveErrors >= maxConsecutiveSyncErrors {
+				return fmt.Errorf("controller %q failed its last %d syncs in a row: %w", c.name, consecutiveErrors, lastErr)
+			}
+			return nil
+		}),
+	}
+	return append(checks, c.healthCheckers...)
+}
+
+// runPostStartHooks launches every registered PostStartHook in its own goroutine. hookContext is the
+// SyncContext made available to each hook.
+func (c *controller) runPostStartHooks(ctx context.Context, hookContext SyncContext) {
+	for i := range c.postStartHooks {
+		hook := c.postStartHooks[i]
+		go func() {
+			defer utilruntime.HandleCrash()
+			if err := hook(ctx, hookContext); err != nil {
+				hookContext.Logger().Error(err, "post start hook failed")
+				c.eventRecorder.Warningf("PostStartHookError", "post start hook failed: %v", err)
+			}
+		}()
+	}
+}
@@ -0,0 +1,138 @@
+package factory
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	"github.com/openshift/library-go/pkg/operator/events"
+)
+
+func findHealthCheck(t *testing.T, controller Controller, name string) func() error {
+	t.Helper()
+	for _, check := range controller.HealthChecks() {
+		if check.Name() == name {
+			return func() error { return check.Check(nil) }
+		}
+	}
+	t.Fatalf("no health check named %q, got %v", name, controller.HealthChecks())
+	return nil
+}
+
+func TestControllerHealthChecksHappyPath(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.TODO())
+	factory := New().ResyncEvery(100 * time.Millisecond)
+
+	controllerSynced := make(chan struct{})
+	controller := factory.WithSync(func(ctx context.Context, controllerContext SyncContext) error {
+		close(controllerSynced)
+		return nil
+	}).ToController("HealthyController", events.NewInMemoryRecorder("healthy-controller"))
+
+	hasSynced := findHealthCheck(t, controller, "HealthyController-has-synced")
+	informersSynced := findHealthCheck(t, controller, "HealthyController-informers-synced")
+	syncHealthy := findHealthCheck(t, controller, "HealthyController-sync")
+
+	if err := hasSynced(); err == nil {
+		t.Fatal("expected has-synced check to fail before the first sync")
+	}
+
+	go controller.Run(ctx, 1)
+	defer cancel()
+
+	select {
+	case <-controllerSynced:
+	case <-time.After(10 * time.Second):
+		t.Fatal("controller never synced")
+	}
+
+	// controllerSynced is closed from inside the sync func, before the controller records that sync's
+	// result, so wait for that bookkeeping before checking what it reports.
+	if err := wait.PollImmediate(10*time.Millisecond, 10*time.Second, func() (bool, error) {
+		return !controller.LastSyncTime().IsZero(), nil
+	}); err != nil {
+		t.Fatal("controller never recorded its first sync")
+	}
+
+	if err := hasSynced(); err != nil {
+		t.Errorf("expected has-synced check to pass after a sync, got: %v", err)
+	}
+	if err := informersSynced(); err != nil {
+		t.Errorf("expected informers-synced check to pass when there are no informers, got: %v", err)
+	}
+	if err := syncHealthy(); err != nil {
+		t.Errorf("expected sync check to pass after a successful sync, got: %v", err)
+	}
+	if err := controller.LastSyncError(); err != nil {
+		t.Errorf("expected LastSyncError to be nil, got: %v", err)
+	}
+	if controller.LastSyncTime().IsZero() {
+		t.Error("expected LastSyncTime to be set after a sync")
+	}
+}
+
+func TestControllerHealthChecksDegraded(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+	factory := New().ResyncEvery(10 * time.Millisecond)
+
+	failuresSeen := make(chan struct{})
+	syncCount := 0
+	controller := factory.WithSync(func(ctx context.Context, controllerContext SyncContext) error {
+		syncCount++
+		if syncCount == maxConsecutiveSyncErrors {
+			close(failuresSeen)
+		}
+		return errors.New("synthetic failure")
+	}).ToController("DegradedController", events.NewInMemoryRecorder("degraded-controller"))
+
+	syncHealthy := findHealthCheck(t, controller, "DegradedController-sync")
+
+	go controller.Run(ctx, 1)
+
+	select {
+	case <-failuresSeen:
+	case <-time.After(10 * time.Second):
+		t.Fatal("controller never accumulated enough consecutive failures")
+	}
+
+	// failuresSeen only tells us the sync func has run maxConsecutiveSyncErrors times; the controller
+	// records each result (and flips the health check) after the sync func returns, so poll rather than
+	// assuming that bookkeeping has already happened by the time we wake up from the channel receive.
+	err := wait.PollImmediate(10*time.Millisecond, 10*time.Second, func() (bool, error) {
+		return syncHealthy() != nil, nil
+	})
+	if err != nil {
+		t.Fatal("expected sync check to fail after repeated sync errors")
+	}
+	if controller.LastSyncError() == nil {
+		t.Error("expected LastSyncError to be set after a failing sync")
+	}
+}
+
+func TestControllerPostStartHook(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+
+	hookRan := make(chan string, 1)
+	controller := New().WithPostStartHook(func(ctx context.Context, syncContext SyncContext) error {
+		hookRan <- syncContext.QueueKey()
+		return nil
+	}).WithSync(func(ctx context.Context, controllerContext SyncContext) error {
+		return nil
+	}).ToController("HookedController", events.NewInMemoryRecorder("hooked-controller"))
+
+	go controller.Run(ctx, 1)
+
+	select {
+	case key := <-hookRan:
+		if key != DefaultQueueKey {
+			t.Errorf("expected post start hook to see queue key %q, got %q", DefaultQueueKey, key)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("post start hook never ran")
+	}
+}
@@ -0,0 +1,103 @@
+package factory
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apiserver/pkg/server/healthz"
+	"k8s.io/client-go/util/workqueue"
+
+	"github.com/openshift/library-go/pkg/operator/events"
+)
+
+// Controller is an interface for a controller that can be started with Run(ctx, workers). A Controller is
+// produced by calling Factory.ToController().
+type Controller interface {
+	// Run runs the controller and blocks until the context is cancelled and all workers have finished
+	// processing the current item in the queue. The workers count determines how many keys are processed
+	// in parallel. A controller built with Factory.WithLeaderElection also returns from Run once it loses
+	// the lease, after its workers have finished; it does not try to reacquire the lease.
+	Run(ctx context.Context, workers int)
+
+	// Name returns the name of the controller as passed to Factory.ToController().
+	Name() string
+
+	// HealthChecks returns healthz.HealthChecker instances reporting on this controller's health, so a
+	// consumer can wire it into an existing /healthz or /readyz mux instead of reinventing the pattern
+	// for every operator.
+	HealthChecks() []healthz.HealthChecker
+
+	// LastSyncError returns the error returned by the most recently completed sync, or nil if the last
+	// sync succeeded or no sync has completed yet.
+	LastSyncError() error
+
+	// LastSyncTime returns the time the most recently completed sync finished, or the zero time if no
+	// sync has completed yet.
+	LastSyncTime() time.Time
+
+	// PrepareRun waits for the controller's informers to sync and registers the event handlers that feed
+	// its queue, but does not start workers, the resync loop or post start hooks. Run calls it as its
+	// first step; test harnesses such as factory/factorytesting call it directly so they can seed
+	// informers and drive Queue()/Sync() themselves instead of running real workers. It returns false if
+	// ctx was cancelled before the caches synced.
+	PrepareRun(ctx context.Context) bool
+
+	// Queue returns the workqueue the controller pops keys from. It is exposed, together with PrepareRun
+	// and Sync, so test harnesses can drive a controller one key at a time deterministically.
+	Queue() workqueue.RateLimitingInterface
+
+	// Sync invokes the controller's configured SyncFunc directly for key, recording the result the same
+	// way a worker processing that key from the queue would. It does not read from or otherwise touch the
+	// queue itself.
+	Sync(ctx context.Context, key string) error
+}
+
+// SyncFunc is a function that gets called when the controller triggers a sync for a given key. The context
+// passed to this function is cancelled when the controller shuts down and carries the per-key logger, so a
+// contextual logging call like klog.FromContext(ctx) returns the same logger as controllerContext.Logger().
+type SyncFunc func(ctx context.Context, controllerContext SyncContext) error
+
+// SyncContext carries the per-sync state that is made available to a SyncFunc: the workqueue it was popped
+// from, the key being synced and an event recorder scoped to the owning controller.
+type SyncContext interface {
+	// Queue returns the workqueue the controller uses. A SyncFunc can use it to requeue additional keys.
+	Queue() workqueue.RateLimitingInterface
+
+	// QueueKey returns the key that is currently being processed.
+	QueueKey() string
+
+	// Recorder returns the event recorder associated with this controller.
+	Recorder() events.Recorder
+
+	// Logger returns a logr.Logger already enriched with the controller name and the queue key, plus the
+	// worker index when the sync runs on one of the controller's workers, so a SyncFunc does not have to
+	// repeat those key/value pairs on every log call.
+	Logger() logr.Logger
+}
+
+// ObjectQueueKeyFunc computes the queue key that the controller should use for the given object. It is
+// consulted by the informer event handlers registered through Factory.WithInformersQueueKeyFunc.
+type ObjectQueueKeyFunc func(obj runtime.Object) string
+
+// ObjectQueueKeysFunc computes the queue keys that the controller should enqueue for the given object. It
+// is consulted by the informer event handlers registered through Factory.WithInformersQueueKeysFunc, and
+// lets a single object fan out into several keys (for example, a shared ConfigMap that several owners
+// watch by their own key).
+type ObjectQueueKeysFunc func(obj runtime.Object) []string
+
+// EventFilterFunc decides whether an informer Add/Update/Delete event should enqueue a key at all. It
+// receives the (old, new) object pair as the informer's event handler would: old is nil for an Add event,
+// new is nil for a Delete event. It is consulted by the informer event handlers registered through
+// Factory.WithFilteredEventsInformers.
+type EventFilterFunc func(oldObj, newObj interface{}) bool
+
+// DefaultQueueKey is used as the queue key for informers registered through Factory.WithInformers, where no
+// ObjectQueueKeyFunc is given and the controller does not care which object triggered the sync.
+const DefaultQueueKey = "key"
+
+// DeadLetterHandler is called for a key that has failed to sync as many times as a controller's configured
+// max retries, once that key has been given up on. lastErr is the error returned by its final failed sync. See
+// Factory.WithDeadLetterHandler.
+type DeadLetterHandler func(ctx context.Context, key string, lastErr error)
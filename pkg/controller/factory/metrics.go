@@ -0,0 +1,18 @@
+package factory
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// deadLetterTotal counts keys a controller gave up on after exhausting their retry budget, labeled by
+// controller name so it can be graphed per-controller without label cardinality explosions (the key itself
+// is deliberately not a label).
+var deadLetterTotal = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "controller_key_dead_letter_total",
+		Help: "Number of keys a controller stopped retrying after reaching its max retry count, by controller.",
+	},
+	[]string{"controller"},
+)
+
+func init() {
+	prometheus.MustRegister(deadLetterTotal)
+}